@@ -0,0 +1,284 @@
+package gmtry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/BurntSushi/toml"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/ftl/gmtry/pb"
+)
+
+// Document is the top-level unit a Codec (de)serializes: the
+// monitor-topology Profiles plus any named Layouts the user explicitly
+// saved.
+type Document struct {
+	Profiles Profiles
+	Layouts  Layouts
+}
+
+// NewDocument instance.
+func NewDocument() Document {
+	return Document{
+		Profiles: NewProfiles(),
+		Layouts:  NewLayouts(),
+	}
+}
+
+// Codec marshals and unmarshals the Document that Geometry persists. The
+// built-in codecs are NewProtoCodec (the original, compact format),
+// NewJSONCodec, and NewTOMLCodec (both human-inspectable and
+// hand-editable, a common request for apps whose users want to script
+// their window layouts).
+type Codec interface {
+	// Name identifies the format, e.g. "proto", "json" or "toml". It is
+	// stored in the header of every file written by Store, so Restore
+	// can pick the matching built-in Codec even if a different one is
+	// configured by the time it is read back. A header naming any other
+	// format is only read by a configured Codec of that name.
+	Name() string
+	Marshal(Document) ([]byte, error)
+	Unmarshal([]byte) (Document, error)
+}
+
+// magic precedes the header of every file written by a Codec. Data
+// without it is decoded as a bare protobuf-encoded ProfileSet holding
+// nothing but Profiles; a header-less bare protobuf Windows message is
+// parsed as a ProfileSet as well, which does not recover its windows.
+var magic = []byte("GMTRY")
+
+func encode(codec Codec, doc Document) ([]byte, error) {
+	payload, err := codec.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal geometry as %s: %w", codec.Name(), err)
+	}
+
+	name := codec.Name()
+	header := make([]byte, 0, len(magic)+1+len(name))
+	header = append(header, magic...)
+	header = append(header, byte(len(name)))
+	header = append(header, name...)
+	return append(header, payload...), nil
+}
+
+func decode(data []byte, codec Codec) (Document, error) {
+	if !bytes.HasPrefix(data, magic) {
+		doc := NewDocument()
+		profiles, err := protoCodec{}.unmarshalLegacyProfileSet(data)
+		if err != nil {
+			return doc, err
+		}
+		doc.Profiles = profiles
+		return doc, nil
+	}
+
+	rest := data[len(magic):]
+	if len(rest) < 1 {
+		return NewDocument(), fmt.Errorf("truncated geometry header")
+	}
+	nameLen := int(rest[0])
+	rest = rest[1:]
+	if len(rest) < nameLen {
+		return NewDocument(), fmt.Errorf("truncated geometry header")
+	}
+	name := string(rest[:nameLen])
+	payload := rest[nameLen:]
+
+	if name != codec.Name() {
+		if known, ok := builtinCodecs[name]; ok {
+			codec = known
+		} else {
+			return NewDocument(), fmt.Errorf("stored geometry uses unknown format %q", name)
+		}
+	}
+	return codec.Unmarshal(payload)
+}
+
+// LoadDocument from the given reader, decoded with codec. If codec is
+// nil, NewProtoCodec() is used. Data without a Codec header is decoded
+// as a header-less protobuf ProfileSet, regardless of which codec is
+// given.
+func LoadDocument(r io.Reader, codec Codec) (Document, error) {
+	buffer, err := ioutil.ReadAll(r)
+	if err != nil {
+		return NewDocument(), err
+	}
+	if codec == nil {
+		codec = NewProtoCodec()
+	}
+	return decode(buffer, codec)
+}
+
+// Store the document to the given writer, encoded with codec. If codec
+// is nil, NewProtoCodec() is used.
+func (doc Document) Store(writer io.Writer, codec Codec) error {
+	if codec == nil {
+		codec = NewProtoCodec()
+	}
+	data, err := encode(codec, doc)
+	if err != nil {
+		return err
+	}
+	n, err := writer.Write(data)
+	if err != nil {
+		return fmt.Errorf("cannot write geometry: %w", err)
+	}
+	if n != len(data) {
+		return fmt.Errorf("could only write %d of %d bytes", n, len(data))
+	}
+	return nil
+}
+
+// builtinCodecs allows decode to fall back to whichever built-in Codec
+// originally wrote a file, even if a different one is configured now.
+var builtinCodecs = map[string]Codec{
+	"proto": NewProtoCodec(),
+	"json":  NewJSONCodec(),
+	"toml":  NewTOMLCodec(),
+}
+
+// protoCodec is the original, compact Codec, based on the pb.Document
+// protobuf message.
+type protoCodec struct{}
+
+// NewProtoCodec instance.
+func NewProtoCodec() Codec {
+	return protoCodec{}
+}
+
+func (protoCodec) Name() string {
+	return "proto"
+}
+
+func (protoCodec) Marshal(doc Document) ([]byte, error) {
+	pbDoc := pb.Document{
+		Profiles: profilesToPB(doc.Profiles),
+		Layouts:  layoutsToPB(doc.Layouts),
+	}
+	bytes, err := proto.Marshal(&pbDoc)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot marshal the geometry")
+	}
+	return bytes, nil
+}
+
+func (protoCodec) Unmarshal(data []byte) (Document, error) {
+	pbDoc := new(pb.Document)
+	err := proto.Unmarshal(data, pbDoc)
+	if err != nil {
+		return NewDocument(), err
+	}
+	return Document{
+		Profiles: profilesFromPB(pbDoc.Profiles),
+		Layouts:  layoutsFromPB(pbDoc.Layouts),
+	}, nil
+}
+
+// unmarshalLegacyProfileSet reads a header-less pb.ProfileSet, the format
+// decode assumes for any data without a Codec header.
+func (protoCodec) unmarshalLegacyProfileSet(data []byte) (Profiles, error) {
+	pbProfileSet := new(pb.ProfileSet)
+	err := proto.Unmarshal(data, pbProfileSet)
+	if err != nil {
+		return NewProfiles(), err
+	}
+	return profilesFromPB(pbProfileSet), nil
+}
+
+func profilesToPB(profiles Profiles) *pb.ProfileSet {
+	pbProfileSet := new(pb.ProfileSet)
+	for key, windows := range profiles {
+		pbProfileSet.Profiles = append(pbProfileSet.Profiles, &pb.Profile{Key: key, Windows: windowsToPB(windows)})
+	}
+	return pbProfileSet
+}
+
+func profilesFromPB(pbProfileSet *pb.ProfileSet) Profiles {
+	result := NewProfiles()
+	if pbProfileSet == nil {
+		return result
+	}
+	for _, pbProfile := range pbProfileSet.Profiles {
+		result[pbProfile.Key] = windowsFromPB(pbProfile.Windows)
+	}
+	return result
+}
+
+func layoutsToPB(layouts Layouts) *pb.LayoutSet {
+	pbLayoutSet := new(pb.LayoutSet)
+	for name, windows := range layouts {
+		pbLayoutSet.Layouts = append(pbLayoutSet.Layouts, &pb.Layout{Name: name, Windows: windowsToPB(windows)})
+	}
+	return pbLayoutSet
+}
+
+func layoutsFromPB(pbLayoutSet *pb.LayoutSet) Layouts {
+	result := NewLayouts()
+	if pbLayoutSet == nil {
+		return result
+	}
+	for _, pbLayout := range pbLayoutSet.Layouts {
+		result[pbLayout.Name] = windowsFromPB(pbLayout.Windows)
+	}
+	return result
+}
+
+// jsonCodec stores the geometry as indented, human-readable JSON.
+type jsonCodec struct{}
+
+// NewJSONCodec instance.
+func NewJSONCodec() Codec {
+	return jsonCodec{}
+}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+func (jsonCodec) Marshal(doc Document) ([]byte, error) {
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func (jsonCodec) Unmarshal(data []byte) (Document, error) {
+	result := NewDocument()
+	err := json.Unmarshal(data, &result)
+	if err != nil {
+		return NewDocument(), err
+	}
+	return result, nil
+}
+
+// tomlCodec stores the geometry as human-readable, hand-editable TOML.
+type tomlCodec struct{}
+
+// NewTOMLCodec instance.
+func NewTOMLCodec() Codec {
+	return tomlCodec{}
+}
+
+func (tomlCodec) Name() string {
+	return "toml"
+}
+
+func (tomlCodec) Marshal(doc Document) ([]byte, error) {
+	buffer := bytes.NewBuffer(nil)
+	err := toml.NewEncoder(buffer).Encode(doc)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (tomlCodec) Unmarshal(data []byte) (Document, error) {
+	result := NewDocument()
+	err := toml.Unmarshal(data, &result)
+	if err != nil {
+		return NewDocument(), err
+	}
+	return result, nil
+}
@@ -0,0 +1,62 @@
+package gmtry
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func someDocument() Document {
+	windows := NewWindows()
+	main := windows.Get("main")
+	main.SetPosition(1, 2)
+	main.SetSize(3, 4)
+	main.SetMaximized(true)
+
+	doc := NewDocument()
+	doc.Profiles["laptop"] = windows
+	doc.Layouts["coding"] = windows
+	return doc
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	for _, codec := range []Codec{NewProtoCodec(), NewJSONCodec(), NewTOMLCodec()} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			doc := someDocument()
+
+			data, err := codec.Marshal(doc)
+			require.NoError(t, err)
+
+			loaded, err := codec.Unmarshal(data)
+			require.NoError(t, err)
+			assert.Equal(t, doc.Profiles["laptop"].Get("main"), loaded.Profiles["laptop"].Get("main"))
+			assert.Equal(t, doc.Layouts["coding"].Get("main"), loaded.Layouts["coding"].Get("main"))
+		})
+	}
+}
+
+func TestLoadDocument_ReadsLegacyHeaderlessProtoFile(t *testing.T) {
+	doc := someDocument()
+	legacy, err := proto.Marshal(profilesToPB(doc.Profiles))
+	require.NoError(t, err)
+
+	loaded, err := LoadDocument(bytes.NewReader(legacy), NewJSONCodec())
+	require.NoError(t, err)
+	assert.Equal(t, doc.Profiles["laptop"].Get("main"), loaded.Profiles["laptop"].Get("main"))
+	assert.Empty(t, loaded.Layouts)
+}
+
+func TestDocumentStore_WritesTheConfiguredFormat(t *testing.T) {
+	doc := someDocument()
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Store(buffer, NewJSONCodec())
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(buffer.Bytes(), []byte("GMTRY\x04json")))
+
+	loaded, err := LoadDocument(bytes.NewReader(buffer.Bytes()), NewProtoCodec())
+	require.NoError(t, err)
+	assert.Equal(t, doc.Profiles["laptop"].Get("main"), loaded.Profiles["laptop"].Get("main"))
+}
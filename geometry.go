@@ -1,14 +1,13 @@
 package gmtry
 
 import (
+	"bytes"
+	"context"
+	stderrors "errors"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"log"
 	"os"
-
-	"github.com/golang/protobuf/proto"
-	"github.com/pkg/errors"
+	"sync"
 
 	"github.com/ftl/gmtry/pb"
 )
@@ -16,21 +15,51 @@ import (
 // ID of a window.
 type ID string
 
-func NewGeometry(filename string) *Geometry {
+// NewGeometry that persists its windows through the given Storage,
+// encoded with the given Codec. The monitors provider and the codec are
+// both optional: pass nil for monitors when the application does not
+// care about monitor topology, and nil for codec to use NewProtoCodec().
+func NewGeometry(storage Storage, monitors MonitorProvider, codec Codec) *Geometry {
+	if codec == nil {
+		codec = NewProtoCodec()
+	}
 	return &Geometry{
-		filename:     filename,
+		storage:      storage,
+		monitors:     monitors,
+		codec:        codec,
 		connectables: NewConnectables(),
+		profiles:     NewProfiles(),
+		layouts:      NewLayouts(),
 		windows:      NewWindows(),
 	}
 }
 
 type Geometry struct {
-	filename     string
+	mu           sync.Mutex
+	storage      Storage
+	monitors     MonitorProvider
+	codec        Codec
 	connectables Connectables
+	profiles     Profiles
+	profileKey   string
+	layouts      Layouts
 	windows      Windows
+	subscribers  []func(ID, *Window)
+	changed      chan struct{}
 }
 
 func (g *Geometry) Add(id ID, connectable Connectable) {
+	monitors, _ := g.fetchMonitors()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.add(id, connectable, monitors)
+}
+
+// add is the unlocked core of Add; Notify uses update instead. monitors
+// is the topology fetched by the caller before taking the lock; see
+// fetchMonitors.
+func (g *Geometry) add(id ID, connectable Connectable, monitors []Monitor) *Window {
 	var window *Window
 	window, ok := g.windows[id]
 	if ok {
@@ -40,47 +69,164 @@ func (g *Geometry) Add(id ID, connectable Connectable) {
 		window.SetPosition(connectable.GetPosition())
 		window.SetSize(connectable.GetSize())
 		window.SetMaximized(connectable.IsMaximized())
+		locateWindow(window, connectable, monitors)
 		g.windows[id] = window
 	}
 	g.connectables[id] = connectable
+	return window
+}
+
+// update refreshes the tracked window for id from connectable's current
+// state, creating the window if id is not yet tracked. Unlike add, which
+// treats the stored window as the source of truth and pushes it onto a
+// newly (re-)connected connectable, update treats connectable as the
+// source of truth: it is used by Notify, where the connectable itself
+// just changed and the stored window must catch up to it. monitors is
+// the topology fetched by the caller before taking the lock; see
+// fetchMonitors.
+func (g *Geometry) update(id ID, connectable Connectable, monitors []Monitor) *Window {
+	window, ok := g.windows[id]
+	if !ok {
+		window = &Window{ID: id}
+		g.windows[id] = window
+	}
+	window.SetPosition(connectable.GetPosition())
+	window.SetSize(connectable.GetSize())
+	window.SetMaximized(connectable.IsMaximized())
+	locateWindow(window, connectable, monitors)
+	g.connectables[id] = connectable
+	return window
+}
+
+// fetchMonitors returns the monitor topology currently reported by the
+// configured MonitorProvider, or ok=false if none is configured or it
+// failed. It intentionally does not take g.mu: MonitorProvider exists so
+// GUI toolkits can supply topology, which typically requires a
+// round-trip to the toolkit's own event loop and can be slow or, for a
+// reentrant implementation that calls back into Geometry, would deadlock
+// on the non-reentrant mutex. Callers fetch monitors before taking the
+// lock instead of holding it for the duration of the call.
+func (g *Geometry) fetchMonitors() ([]Monitor, bool) {
+	if g.monitors == nil {
+		return nil, false
+	}
+	monitors, err := g.monitors.Monitors()
+	if err != nil {
+		log.Printf("cannot determine monitor topology: %v", err)
+		return nil, false
+	}
+	return monitors, true
+}
+
+// locateWindow fills in the monitor, workspace and scale a window was
+// captured under, so that Restore can later pick the Profile matching
+// the current monitor topology. monitors is the topology fetched via
+// fetchMonitors, or nil if none is configured or available.
+func locateWindow(window *Window, connectable Connectable, monitors []Monitor) {
+	if workspaceAware, ok := connectable.(WorkspaceAware); ok {
+		window.SetWorkspace(workspaceAware.Workspace())
+	}
+
+	if monitors == nil {
+		return
+	}
+	x, y := connectable.GetPosition()
+	monitor, ok := MonitorAt(monitors, x, y)
+	if !ok {
+		return
+	}
+	window.SetMonitor(monitor.ID)
+	window.SetScale(monitor.Scale)
+}
+
+// currentProfileKey returns the topology key of the given monitor
+// topology, or "" if it was not available. See fetchMonitors.
+func currentProfileKey(monitors []Monitor, ok bool) string {
+	if !ok {
+		return ""
+	}
+	return TopologyKey(monitors)
 }
 
 func (g *Geometry) Get(id ID) *Window {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 	return g.windows.Get(id)
 }
 
-func (g *Geometry) Store() error {
-	f, err := os.OpenFile(g.filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+// Store the current geometry of all windows through the configured Storage.
+func (g *Geometry) Store(ctx context.Context) error {
+	g.mu.Lock()
+	g.profiles[g.profileKey] = g.windows
+	g.mu.Unlock()
+
+	return g.persist(ctx)
+}
+
+// persist writes the current profiles and layouts through the configured
+// Storage. Unlike Store, it does not first sync the active profile from
+// g.windows, which matters for the layout API: while a named layout is
+// loaded, g.windows holds that layout's geometry, not the active
+// profile's, and must not overwrite it.
+func (g *Geometry) persist(ctx context.Context) error {
+	g.mu.Lock()
+	doc := Document{Profiles: g.profiles, Layouts: g.layouts}
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Store(buffer, g.codec)
+	g.mu.Unlock()
 	if err != nil {
-		return fmt.Errorf("Cannot open window geometry file %s: %w", g.filename, err)
+		return fmt.Errorf("cannot store window geometry: %w", err)
 	}
-	defer f.Close()
 
-	err = g.windows.Store(f)
+	err = g.storage.Save(ctx, buffer.Bytes())
 	if err != nil {
-		return fmt.Errorf("Cannot store window geometry: %w", err)
+		return fmt.Errorf("cannot save window geometry: %w", err)
 	}
 
-	log.Printf("Stored window geometry in %s", f.Name())
+	log.Print("Stored window geometry")
 	return nil
 }
 
-func (g *Geometry) Restore() error {
-	log.Printf("Loading window geometry from %s", g.filename)
-
-	f, err := os.Open(g.filename)
+// Restore the geometry of all currently registered connectables from the
+// configured Storage. The profile matching the current monitor topology
+// is used; if there is no exact match, the profile with the most
+// monitors in common is used instead, so that plugging or unplugging a
+// display does not dump every window onto the wrong coordinates. It is
+// not an error if there is nothing stored yet.
+func (g *Geometry) Restore(ctx context.Context) error {
+	log.Print("Loading window geometry")
+
+	data, err := g.storage.Load(ctx)
+	if stderrors.Is(err, os.ErrNotExist) {
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("Cannot open %s: %w", g.filename, err)
+		return fmt.Errorf("cannot load window geometry: %w", err)
 	}
-	defer f.Close()
 
-	loaded, err := LoadWindows(f)
+	g.mu.Lock()
+	codec := g.codec
+	g.mu.Unlock()
+	doc, err := LoadDocument(bytes.NewReader(data), codec)
 	if err != nil {
-		return fmt.Errorf("Cannot load window geometry: %w", err)
+		return fmt.Errorf("cannot load window geometry: %w", err)
 	}
 
+	monitors, ok := g.fetchMonitors()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.profiles = doc.Profiles
+	g.layouts = doc.Layouts
+
+	g.profileKey = currentProfileKey(monitors, ok)
+	loaded, ok := g.profiles[g.profileKey]
+	if !ok {
+		loaded = g.profiles.nearest(g.profileKey)
+	}
+
+	g.windows = loaded
 	for id, window := range loaded {
-		g.windows[id] = window
 		connectable, ok := g.connectables[id]
 		if !ok {
 			continue
@@ -98,10 +244,14 @@ type Window struct {
 	Width     int
 	Height    int
 	Maximized bool
+	Monitor   string
+	Workspace int
+	Scale     float64
 }
 
 func (w *Window) String() string {
-	return fmt.Sprintf("Window %s: (%d, %d) (%d x %d) %t", w.ID, w.X, w.Y, w.Width, w.Height, w.Maximized)
+	return fmt.Sprintf("Window %s: (%d, %d) (%d x %d) %t monitor=%s workspace=%d scale=%.2f",
+		w.ID, w.X, w.Y, w.Width, w.Height, w.Maximized, w.Monitor, w.Workspace, w.Scale)
 }
 
 // Apply the window geometry to the given target.
@@ -136,6 +286,28 @@ func (w *Window) SetMaximized(maximized bool) {
 	w.Maximized = maximized
 }
 
+// SetMonitor records the ID of the output this window was on.
+func (w *Window) SetMonitor(id string) {
+	w.Monitor = id
+}
+
+// SetWorkspace records the workspace/desktop index this window was on.
+func (w *Window) SetWorkspace(workspace int) {
+	w.Workspace = workspace
+}
+
+// SetScale records the DPI scale of the monitor this window was on.
+func (w *Window) SetScale(scale float64) {
+	w.Scale = scale
+}
+
+// WorkspaceAware is implemented by connectables that know which
+// workspace/desktop they are currently shown on. Geometry uses this, if
+// available, to tag captured windows with their workspace index.
+type WorkspaceAware interface {
+	Workspace() int
+}
+
 // Applyable represents anything that window geometry can be applied to.
 type Applyable interface {
 	Move(x, y int)
@@ -172,56 +344,62 @@ func NewWindows() Windows {
 	return make(Windows)
 }
 
-// LoadWindows from the given reader.
-func LoadWindows(r io.Reader) (Windows, error) {
-	buffer, err := ioutil.ReadAll(r)
-	if err != nil {
-		return NewWindows(), err
+// clone returns a deep copy of w, so that the result can be handed out
+// and mutated independently of w itself.
+func (w Windows) clone() Windows {
+	result := make(Windows, len(w))
+	for id, window := range w {
+		copied := *window
+		result[id] = &copied
 	}
-	pbWindows := new(pb.Windows)
-	err = proto.Unmarshal(buffer, pbWindows)
-	if err != nil {
-		return NewWindows(), err
+	return result
+}
+
+// windowToPB converts a Window to its protobuf representation.
+func windowToPB(window *Window) *pb.Window {
+	return &pb.Window{
+		Name:      string(window.ID),
+		Position:  &pb.Position{X: int32(window.X), Y: int32(window.Y)},
+		Size:      &pb.Size{Width: int32(window.Width), Height: int32(window.Height)},
+		Maximized: window.Maximized,
+		Monitor:   window.Monitor,
+		Workspace: int32(window.Workspace),
+		Scale:     window.Scale,
 	}
-	result := NewWindows()
-	for _, pbWindow := range pbWindows.Windows {
-		window := Window{
-			ID:        ID(pbWindow.Name),
-			X:         int(pbWindow.Position.X),
-			Y:         int(pbWindow.Position.Y),
-			Width:     int(pbWindow.Size.Width),
-			Height:    int(pbWindow.Size.Height),
-			Maximized: pbWindow.Maximized,
-		}
-		result[window.ID] = &window
+}
+
+// windowFromPB converts a protobuf Window to a Window.
+func windowFromPB(pbWindow *pb.Window) *Window {
+	return &Window{
+		ID:        ID(pbWindow.Name),
+		X:         int(pbWindow.Position.X),
+		Y:         int(pbWindow.Position.Y),
+		Width:     int(pbWindow.Size.Width),
+		Height:    int(pbWindow.Size.Height),
+		Maximized: pbWindow.Maximized,
+		Monitor:   pbWindow.Monitor,
+		Workspace: int(pbWindow.Workspace),
+		Scale:     pbWindow.Scale,
 	}
-	return result, nil
 }
 
-// Store windows to the given writer.
-func (w Windows) Store(writer io.Writer) error {
+// windowsToPB converts Windows to its protobuf representation.
+func windowsToPB(windows Windows) *pb.Windows {
 	pbWindows := new(pb.Windows)
-	for _, window := range w {
-		pbWindow := pb.Window{
-			Name:      string(window.ID),
-			Position:  &pb.Position{X: int32(window.X), Y: int32(window.Y)},
-			Size:      &pb.Size{Width: int32(window.Width), Height: int32(window.Height)},
-			Maximized: window.Maximized,
-		}
-		pbWindows.Windows = append(pbWindows.Windows, &pbWindow)
-	}
-	bytes, err := proto.Marshal(pbWindows)
-	if err != nil {
-		return errors.Wrap(err, "cannot marshal the windows")
-	}
-	n, err := writer.Write(bytes)
-	if err != nil {
-		return errors.Wrap(err, "cannot write windows")
+	for _, window := range windows {
+		pbWindows.Windows = append(pbWindows.Windows, windowToPB(window))
 	}
-	if n != len(bytes) {
-		return errors.Errorf("could only write %d of %d bytes", n, len(bytes))
+	return pbWindows
+}
+
+// windowsFromPB converts a protobuf Windows message to Windows.
+func windowsFromPB(pbWindows *pb.Windows) Windows {
+	result := NewWindows()
+	for _, pbWindow := range pbWindows.Windows {
+		window := windowFromPB(pbWindow)
+		result[window.ID] = window
 	}
-	return nil
+	return result
 }
 
 // Get the window with the given ID.
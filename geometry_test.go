@@ -23,11 +23,16 @@ func TestRoundtrip(t *testing.T) {
 	initialDialog.SetSize(300, 400)
 	initialDialog.SetMaximized(false)
 
+	doc := NewDocument()
+	doc.Profiles[""] = initials
+
 	buffer := bytes.NewBuffer([]byte{})
-	initials.Store(buffer)
+	err := doc.Store(buffer, nil)
+	require.NoError(t, err)
 
-	loaded, err := LoadWindows(buffer)
+	loadedDoc, err := LoadDocument(buffer, nil)
 	require.NoError(t, err)
+	loaded := loadedDoc.Profiles[""]
 
 	assert.Equal(t, initialMain, loaded.Get(main))
 	assert.Equal(t, initialDialog, loaded.Get(dialog))
@@ -46,18 +51,23 @@ func TestRoundtripWithFile(t *testing.T) {
 	initialDialog.SetSize(300, 400)
 	initialDialog.SetMaximized(false)
 
+	doc := NewDocument()
+	doc.Profiles[""] = initials
+
 	writeFile, err := ioutil.TempFile("", "TestRoundtripWithFile")
 	require.NoError(t, err)
 	defer writeFile.Close()
 
-	initials.Store(writeFile)
+	err = doc.Store(writeFile, nil)
+	require.NoError(t, err)
 
 	readFile, err := os.Open(writeFile.Name())
 	require.NoError(t, err)
 	defer readFile.Close()
 
-	loaded, err := LoadWindows(readFile)
+	loadedDoc, err := LoadDocument(readFile, nil)
 	require.NoError(t, err)
+	loaded := loadedDoc.Profiles[""]
 
 	assert.Equal(t, initialMain, loaded.Get(main))
 	assert.Equal(t, initialDialog, loaded.Get(dialog))
@@ -73,7 +83,7 @@ func TestAdd_ShouldStoreConnectableGeometry(t *testing.T) {
 			Height: 200,
 		},
 	}
-	g := NewGeometry("")
+	g := NewGeometry(NewMemStorage(), nil, nil)
 	g.Add(c.ID, c)
 	w := g.Get("c")
 
@@ -90,7 +100,7 @@ func TestAddAgain_ShouldRestoreGeometryOnConnectable(t *testing.T) {
 		},
 	}
 	c2 := new(testConnectable)
-	g := NewGeometry("")
+	g := NewGeometry(NewMemStorage(), nil, nil)
 	g.Add("c", c1)
 	g.Add("c", c2)
 	assert.Equal(t, c1, c2)
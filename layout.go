@@ -0,0 +1,83 @@
+package gmtry
+
+import (
+	"context"
+	"fmt"
+)
+
+// Layouts maps a user-chosen name to the Windows captured under it.
+type Layouts map[string]Windows
+
+// NewLayouts instance.
+func NewLayouts() Layouts {
+	return make(Layouts)
+}
+
+// SaveLayout captures the geometry of every currently registered
+// Connectable under name and persists it, so it can later be restored
+// with LoadLayout, e.g. from an application hotkey that switches between
+// a "coding", "email" or "presentation" layout.
+func (g *Geometry) SaveLayout(ctx context.Context, name string) error {
+	monitors, _ := g.fetchMonitors()
+
+	g.mu.Lock()
+	windows := NewWindows()
+	for id, connectable := range g.connectables {
+		window := windows.Get(id)
+		window.SetPosition(connectable.GetPosition())
+		window.SetSize(connectable.GetSize())
+		window.SetMaximized(connectable.IsMaximized())
+		locateWindow(window, connectable, monitors)
+	}
+	g.layouts[name] = windows
+	g.mu.Unlock()
+
+	return g.persist(ctx)
+}
+
+// LoadLayout applies the named layout to every currently registered
+// Connectable and makes it the active geometry, so that it is what gets
+// updated by subsequent Add/Notify calls. It does not touch the profile
+// of the current monitor topology: a loaded layout is not a substitute
+// for the active profile until it is explicitly saved as one.
+func (g *Geometry) LoadLayout(ctx context.Context, name string) error {
+	g.mu.Lock()
+	windows, ok := g.layouts[name]
+	if !ok {
+		g.mu.Unlock()
+		return fmt.Errorf("no layout named %q", name)
+	}
+
+	g.windows = windows.clone()
+	for id, window := range g.windows {
+		connectable, ok := g.connectables[id]
+		if !ok {
+			continue
+		}
+		window.Apply(connectable)
+	}
+	g.mu.Unlock()
+
+	return g.persist(ctx)
+}
+
+// ListLayouts returns the names of all saved layouts.
+func (g *Geometry) ListLayouts() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	names := make([]string, 0, len(g.layouts))
+	for name := range g.layouts {
+		names = append(names, name)
+	}
+	return names
+}
+
+// DeleteLayout removes the named layout. It is not an error if no such
+// layout exists.
+func (g *Geometry) DeleteLayout(ctx context.Context, name string) error {
+	g.mu.Lock()
+	delete(g.layouts, name)
+	g.mu.Unlock()
+
+	return g.persist(ctx)
+}
@@ -0,0 +1,70 @@
+package gmtry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveLoadLayout(t *testing.T) {
+	coding := &testConnectable{Window: Window{ID: "editor", X: 0, Y: 0, Width: 800, Height: 600}}
+	g := NewGeometry(NewMemStorage(), nil, nil)
+	g.Add(coding.ID, coding)
+	ctx := context.Background()
+
+	err := g.SaveLayout(ctx, "coding")
+	require.NoError(t, err)
+
+	coding.Move(100, 100)
+	coding.Resize(400, 300)
+
+	err = g.LoadLayout(ctx, "coding")
+	require.NoError(t, err)
+	assert.Equal(t, 0, coding.X)
+	assert.Equal(t, 0, coding.Y)
+	assert.Equal(t, 800, coding.Width)
+	assert.Equal(t, 600, coding.Height)
+}
+
+func TestLoadLayout_DoesNotAliasOrOverwriteSavedLayoutOrProfile(t *testing.T) {
+	c := &testConnectable{Window: Window{ID: "editor", X: 0, Y: 0, Width: 800, Height: 600}}
+	storage := NewMemStorage()
+	g := NewGeometry(storage, nil, nil)
+	g.Add(c.ID, c)
+	ctx := context.Background()
+
+	require.NoError(t, g.Store(ctx)) // establishes the active profile at (0, 0)
+	require.NoError(t, g.SaveLayout(ctx, "coding"))
+	require.NoError(t, g.LoadLayout(ctx, "coding"))
+
+	// mutating the active geometry after loading a layout must not reach
+	// back into the saved layout or the active profile.
+	c.Move(999, 999)
+	g.Notify(c.ID)
+
+	assert.Equal(t, 0, g.layouts["coding"].Get(c.ID).X)
+	assert.Equal(t, 0, g.profiles[g.profileKey].Get(c.ID).X)
+}
+
+func TestLoadLayout_UnknownName(t *testing.T) {
+	g := NewGeometry(NewMemStorage(), nil, nil)
+
+	err := g.LoadLayout(context.Background(), "does-not-exist")
+	assert.Error(t, err)
+}
+
+func TestListAndDeleteLayout(t *testing.T) {
+	c := &testConnectable{Window: Window{ID: "editor"}}
+	g := NewGeometry(NewMemStorage(), nil, nil)
+	g.Add(c.ID, c)
+	ctx := context.Background()
+
+	require.NoError(t, g.SaveLayout(ctx, "coding"))
+	require.NoError(t, g.SaveLayout(ctx, "email"))
+	assert.ElementsMatch(t, []string{"coding", "email"}, g.ListLayouts())
+
+	require.NoError(t, g.DeleteLayout(ctx, "email"))
+	assert.Equal(t, []string{"coding"}, g.ListLayouts())
+}
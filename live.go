@@ -0,0 +1,94 @@
+package gmtry
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// DefaultAutoSaveDelay is the debounce interval StartAutoSave uses when
+// called with a delay of 0.
+const DefaultAutoSaveDelay = 2 * time.Second
+
+// Notify informs the Geometry that the connectable with the given id has
+// changed (moved, resized, maximized, ...). It updates the in-memory
+// window, calls every subscriber registered via Subscribe, and, if
+// StartAutoSave is running, schedules a debounced Store. Connectables
+// should call this after every change instead of requiring the
+// application to remember to call Store() before shutdown.
+func (g *Geometry) Notify(id ID) {
+	monitors, _ := g.fetchMonitors()
+
+	g.mu.Lock()
+	connectable, ok := g.connectables[id]
+	if !ok {
+		g.mu.Unlock()
+		return
+	}
+	live := g.update(id, connectable, monitors)
+	window := *live // copy: subscribers run below without g.mu held, and
+	// must not race the next Notify/Add mutating the tracked *Window.
+	subscribers := g.subscribers
+	changed := g.changed
+	g.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(id, &window)
+	}
+
+	select {
+	case changed <- struct{}{}:
+	default:
+	}
+}
+
+// Subscribe registers a function that is called with the ID and the new
+// Window every time Notify reports a change.
+func (g *Geometry) Subscribe(subscriber func(ID, *Window)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.subscribers = append(g.subscribers, subscriber)
+}
+
+// StartAutoSave launches a background goroutine that calls Store a
+// debounced delay after the last change reported through Notify, so
+// long-running applications do not lose geometry changes if they are
+// terminated without a clean shutdown (e.g. SIGKILL). The goroutine
+// stops when ctx is cancelled. A delay of 0 uses DefaultAutoSaveDelay.
+func (g *Geometry) StartAutoSave(ctx context.Context, delay time.Duration) {
+	if delay <= 0 {
+		delay = DefaultAutoSaveDelay
+	}
+
+	g.mu.Lock()
+	g.changed = make(chan struct{}, 1)
+	changed := g.changed
+	g.mu.Unlock()
+
+	go func() {
+		timer := time.NewTimer(delay)
+		if !timer.Stop() {
+			<-timer.C
+		}
+		pending := false
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+				pending = true
+				timer.Reset(delay)
+			case <-timer.C:
+				if !pending {
+					continue
+				}
+				pending = false
+				err := g.Store(ctx)
+				if err != nil {
+					log.Printf("cannot auto-save window geometry: %v", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,82 @@
+package gmtry
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotify_UpdatesWindowAndSubscribers(t *testing.T) {
+	c := &testConnectable{Window: Window{ID: "c"}}
+	g := NewGeometry(NewMemStorage(), nil, nil)
+	g.Add(c.ID, c)
+
+	var notifiedID ID
+	var notifiedWindow *Window
+	g.Subscribe(func(id ID, w *Window) {
+		notifiedID = id
+		notifiedWindow = w
+	})
+
+	c.Move(10, 20)
+	g.Notify(c.ID)
+
+	assert.Equal(t, c.ID, notifiedID)
+	require.NotNil(t, notifiedWindow)
+	assert.Equal(t, 10, notifiedWindow.X)
+	assert.Equal(t, 20, notifiedWindow.Y)
+	assert.Equal(t, 10, g.Get(c.ID).X)
+}
+
+func TestNotify_SubscriberReceivesACopyNotTheLiveWindow(t *testing.T) {
+	c := &testConnectable{Window: Window{ID: "c"}}
+	g := NewGeometry(NewMemStorage(), nil, nil)
+	g.Add(c.ID, c)
+
+	var notifiedWindow *Window
+	g.Subscribe(func(id ID, w *Window) {
+		notifiedWindow = w
+	})
+
+	c.Move(10, 20)
+	g.Notify(c.ID)
+	require.NotNil(t, notifiedWindow)
+
+	// a subsequent change must not be visible through the pointer handed
+	// to the subscriber, since that would mean the subscriber holds an
+	// alias of the live *Window that Notify/Add can mutate concurrently.
+	c.Move(30, 40)
+	g.Notify(c.ID)
+
+	assert.Equal(t, 10, notifiedWindow.X)
+	assert.Equal(t, 30, g.Get(c.ID).X)
+}
+
+func TestStartAutoSave_StoresAfterDebounceDelay(t *testing.T) {
+	c := &testConnectable{Window: Window{ID: "c"}}
+	storage := NewMemStorage()
+	g := NewGeometry(storage, nil, nil)
+	g.Add(c.ID, c)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	g.StartAutoSave(ctx, 20*time.Millisecond)
+
+	c.Move(10, 20)
+	g.Notify(c.ID)
+
+	_, err := storage.Load(ctx)
+	assert.Error(t, err, "should not have stored yet")
+
+	time.Sleep(100 * time.Millisecond)
+
+	data, err := storage.Load(ctx)
+	require.NoError(t, err)
+	loaded, err := LoadDocument(bytes.NewReader(data), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 10, loaded.Profiles[""].Get(c.ID).X)
+}
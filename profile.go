@@ -0,0 +1,118 @@
+package gmtry
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Monitor describes one physical output as enumerated by the GUI toolkit
+// in use (X11/Wayland compositors expose this through output
+// enumeration).
+type Monitor struct {
+	ID     string
+	X      int
+	Y      int
+	Width  int
+	Height int
+	Scale  float64
+}
+
+// MonitorProvider supplies the current monitor topology, so that gmtry
+// can pick the right Profile without depending on any particular GUI
+// toolkit (GTK, Qt, ...).
+type MonitorProvider interface {
+	Monitors() ([]Monitor, error)
+}
+
+// MonitorAt returns the monitor that contains the point (x, y), if any.
+func MonitorAt(monitors []Monitor, x, y int) (Monitor, bool) {
+	for _, monitor := range monitors {
+		if x >= monitor.X && x < monitor.X+monitor.Width &&
+			y >= monitor.Y && y < monitor.Y+monitor.Height {
+			return monitor, true
+		}
+	}
+	return Monitor{}, false
+}
+
+// TopologyKey returns the canonical identifier of the given monitor
+// topology. It only depends on the set of monitors and their layout, not
+// on the order they were enumerated in, so the same physical topology
+// always produces the same key.
+func TopologyKey(monitors []Monitor) string {
+	ids := make([]string, len(monitors))
+	for i, m := range monitors {
+		ids[i] = fmt.Sprintf("%s:%dx%d+%d+%d@%.2f", m.ID, m.Width, m.Height, m.X, m.Y, m.Scale)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, "|")
+}
+
+// monitorIDs extracts the set of monitor IDs encoded in a topology key.
+func monitorIDs(key string) map[string]bool {
+	ids := make(map[string]bool)
+	if key == "" {
+		return ids
+	}
+	for _, part := range strings.Split(key, "|") {
+		id := strings.SplitN(part, ":", 2)[0]
+		ids[id] = true
+	}
+	return ids
+}
+
+// Profiles holds the Windows captured under each known monitor topology,
+// keyed by TopologyKey.
+type Profiles map[string]Windows
+
+// NewProfiles instance.
+func NewProfiles() Profiles {
+	return make(Profiles)
+}
+
+// nearest returns the Windows of the profile whose topology shares the
+// most monitor IDs with key, or a new, empty Windows if there is no
+// profile at all. This is the fallback used when no profile matches the
+// current topology exactly, e.g. right after plugging in or removing an
+// external display.
+//
+// Candidates are visited in sorted key order and ties are broken first by
+// the candidate with more monitors overall, then by the lexicographically
+// smallest key, so that the result is deterministic regardless of Go's
+// randomized map iteration order.
+func (p Profiles) nearest(key string) Windows {
+	wanted := monitorIDs(key)
+
+	candidateKeys := make([]string, 0, len(p))
+	for candidateKey := range p {
+		candidateKeys = append(candidateKeys, candidateKey)
+	}
+	sort.Strings(candidateKeys)
+
+	found := false
+	var best Windows
+	bestScore := -1
+	bestSize := -1
+	for _, candidateKey := range candidateKeys {
+		ids := monitorIDs(candidateKey)
+		score := 0
+		for id := range ids {
+			if wanted[id] {
+				score++
+			}
+		}
+		size := len(ids)
+		if !found || score > bestScore || (score == bestScore && size > bestSize) {
+			found = true
+			best = p[candidateKey]
+			bestScore = score
+			bestSize = size
+		}
+	}
+	if !found {
+		return NewWindows()
+	}
+	return best
+}
+
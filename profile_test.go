@@ -0,0 +1,81 @@
+package gmtry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopologyKey_IsOrderIndependent(t *testing.T) {
+	a := []Monitor{
+		{ID: "HDMI-1", Width: 1920, Height: 1080, Scale: 1},
+		{ID: "eDP-1", Width: 1280, Height: 800, Scale: 2},
+	}
+	b := []Monitor{a[1], a[0]}
+
+	assert.Equal(t, TopologyKey(a), TopologyKey(b))
+}
+
+func TestTopologyKey_DiffersForDifferentTopologies(t *testing.T) {
+	singleMonitor := []Monitor{{ID: "eDP-1", Width: 1280, Height: 800, Scale: 2}}
+	dualMonitor := []Monitor{
+		{ID: "eDP-1", Width: 1280, Height: 800, Scale: 2},
+		{ID: "HDMI-1", Width: 1920, Height: 1080, Scale: 1},
+	}
+
+	assert.NotEqual(t, TopologyKey(singleMonitor), TopologyKey(dualMonitor))
+}
+
+func TestMonitorAt(t *testing.T) {
+	monitors := []Monitor{
+		{ID: "eDP-1", X: 0, Y: 0, Width: 1280, Height: 800},
+		{ID: "HDMI-1", X: 1280, Y: 0, Width: 1920, Height: 1080},
+	}
+
+	monitor, ok := MonitorAt(monitors, 1300, 10)
+	assert.True(t, ok)
+	assert.Equal(t, "HDMI-1", monitor.ID)
+
+	_, ok = MonitorAt(monitors, 5000, 5000)
+	assert.False(t, ok)
+}
+
+func TestProfiles_Nearest(t *testing.T) {
+	laptopOnly := []Monitor{{ID: "eDP-1", Width: 1280, Height: 800}}
+	withExternal := []Monitor{
+		{ID: "eDP-1", Width: 1280, Height: 800},
+		{ID: "HDMI-1", Width: 1920, Height: 1080},
+	}
+	unrelated := []Monitor{{ID: "DP-2", Width: 2560, Height: 1440}}
+
+	profiles := NewProfiles()
+	profiles[TopologyKey(laptopOnly)] = Windows{"laptop": {ID: "laptop"}}
+	profiles[TopologyKey(unrelated)] = Windows{"unrelated": {ID: "unrelated"}}
+
+	nearest := profiles.nearest(TopologyKey(withExternal))
+	assert.Equal(t, profiles[TopologyKey(laptopOnly)], nearest)
+}
+
+func TestProfiles_NearestWithoutAnyProfile(t *testing.T) {
+	profiles := NewProfiles()
+
+	assert.Equal(t, NewWindows(), profiles.nearest("anything"))
+}
+
+func TestProfiles_NearestBreaksTiesDeterministically(t *testing.T) {
+	current := []Monitor{{ID: "eDP-1", Width: 1280, Height: 800}}
+	tiedA := []Monitor{{ID: "DP-2", Width: 2560, Height: 1440}}
+	tiedB := []Monitor{{ID: "DP-3", Width: 2560, Height: 1440}}
+
+	profiles := NewProfiles()
+	profiles[TopologyKey(tiedB)] = Windows{"tiedB": {ID: "tiedB"}}
+	profiles[TopologyKey(tiedA)] = Windows{"tiedA": {ID: "tiedA"}}
+
+	// both candidates share zero monitors with current and have the same
+	// size, so the tie must resolve to the lexicographically smallest key
+	// every time, regardless of map iteration order.
+	for i := 0; i < 10; i++ {
+		nearest := profiles.nearest(TopologyKey(current))
+		assert.Equal(t, profiles[TopologyKey(tiedA)], nearest)
+	}
+}
@@ -0,0 +1,141 @@
+package gmtry
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Storage abstracts how a Geometry's window data is persisted, so that
+// callers can plug in local files, in-memory storage for tests, or
+// entirely different backends (network, encrypted, per-user) without
+// gmtry depending on any particular one.
+type Storage interface {
+	// Load the raw geometry data. It returns an error wrapping
+	// os.ErrNotExist when nothing has been stored yet.
+	Load(ctx context.Context) ([]byte, error)
+
+	// Save the raw geometry data, replacing whatever was stored before.
+	Save(ctx context.Context, data []byte) error
+}
+
+// FileStorage stores the geometry data in a local file. Save writes are
+// atomic: the new data is written to a temporary file in the same
+// directory first, then renamed over the target file, so a crash or
+// power loss in the middle of a write can never leave behind a
+// truncated or corrupted geometry file.
+type FileStorage struct {
+	filename string
+}
+
+// NewFileStorage for the given filename.
+func NewFileStorage(filename string) *FileStorage {
+	return &FileStorage{filename: filename}
+}
+
+// Load the geometry data from the file. It returns an error wrapping
+// os.ErrNotExist if the file does not exist yet.
+func (s *FileStorage) Load(ctx context.Context) ([]byte, error) {
+	data, err := ioutil.ReadFile(s.filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", s.filename, err)
+	}
+	return data, nil
+}
+
+// Save the geometry data to the file, atomically.
+func (s *FileStorage) Save(ctx context.Context, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(s.filename); err == nil {
+		mode = info.Mode()
+	}
+
+	dir := filepath.Dir(s.filename)
+	tmpFile, err := ioutil.TempFile(dir, filepath.Base(s.filename)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary file in %s: %w", dir, err)
+	}
+	tmpName := tmpFile.Name()
+	defer os.Remove(tmpName) // no-op once the rename below has succeeded
+
+	_, err = tmpFile.Write(data)
+	if err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("cannot write %s: %w", tmpName, err)
+	}
+	err = tmpFile.Sync()
+	if err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("cannot sync %s: %w", tmpName, err)
+	}
+	err = tmpFile.Close()
+	if err != nil {
+		return fmt.Errorf("cannot close %s: %w", tmpName, err)
+	}
+
+	// os.Rename preserves the source file's mode, not the destination's,
+	// so without this the atomic-write switch would silently tighten the
+	// geometry file from 0644 to ioutil.TempFile's 0600.
+	err = os.Chmod(tmpName, mode)
+	if err != nil {
+		return fmt.Errorf("cannot set permissions on %s: %w", tmpName, err)
+	}
+
+	err = os.Rename(tmpName, s.filename)
+	if err != nil {
+		return fmt.Errorf("cannot rename %s to %s: %w", tmpName, s.filename, err)
+	}
+	return nil
+}
+
+// MemStorage keeps the geometry data in memory. It is primarily useful
+// in tests that want to exercise Geometry.Store/Restore without
+// touching the filesystem.
+type MemStorage struct {
+	data []byte
+}
+
+// NewMemStorage instance.
+func NewMemStorage() *MemStorage {
+	return new(MemStorage)
+}
+
+// Load the geometry data kept in memory. It returns an error wrapping
+// os.ErrNotExist if nothing has been saved yet.
+func (s *MemStorage) Load(ctx context.Context) ([]byte, error) {
+	if s.data == nil {
+		return nil, fmt.Errorf("no data saved yet: %w", os.ErrNotExist)
+	}
+	return s.data, nil
+}
+
+// Save the geometry data in memory.
+func (s *MemStorage) Save(ctx context.Context, data []byte) error {
+	s.data = append([]byte{}, data...)
+	return nil
+}
+
+// DefaultFilename returns the XDG-compliant path where appName should
+// store its window geometry: $XDG_DATA_HOME/appName/gmtry.bin, falling
+// back to $HOME/.local/share/appName/gmtry.bin when XDG_DATA_HOME is not
+// set. The parent directory is created if it does not exist yet.
+func DefaultFilename(appName string) (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot determine home directory: %w", err)
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+
+	dir := filepath.Join(dataHome, appName)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", fmt.Errorf("cannot create %s: %w", dir, err)
+	}
+
+	return filepath.Join(dir, "gmtry.bin"), nil
+}
@@ -0,0 +1,81 @@
+package gmtry
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStorage_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "geometry.bin")
+	storage := NewFileStorage(filename)
+	ctx := context.Background()
+
+	_, err := storage.Load(ctx)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	err = storage.Save(ctx, []byte("hello"))
+	require.NoError(t, err)
+
+	data, err := storage.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), data)
+}
+
+func TestFileStorage_SaveLeavesNoTempFilesBehind(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "geometry.bin")
+	storage := NewFileStorage(filename)
+	ctx := context.Background()
+
+	err := storage.Save(ctx, []byte("hello"))
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "geometry.bin", entries[0].Name())
+}
+
+func TestFileStorage_SavePreservesFilePermissions(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "geometry.bin")
+	storage := NewFileStorage(filename)
+	ctx := context.Background()
+
+	err := storage.Save(ctx, []byte("hello"))
+	require.NoError(t, err)
+
+	info, err := os.Stat(filename)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	require.NoError(t, os.Chmod(filename, 0640))
+	err = storage.Save(ctx, []byte("world"))
+	require.NoError(t, err)
+
+	info, err = os.Stat(filename)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+}
+
+func TestMemStorage_RoundTrip(t *testing.T) {
+	storage := NewMemStorage()
+	ctx := context.Background()
+
+	_, err := storage.Load(ctx)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	err = storage.Save(ctx, []byte("hello"))
+	require.NoError(t, err)
+
+	data, err := storage.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), data)
+}